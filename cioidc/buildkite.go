@@ -0,0 +1,42 @@
+package cioidc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// buildkiteProvider fetches an OIDC token by shelling out to the
+// buildkite-agent binary, which every Buildkite job has on PATH.
+type buildkiteProvider struct{}
+
+func (buildkiteProvider) Name() string { return "buildkite" }
+
+func (p buildkiteProvider) FetchIDToken(ctx context.Context, audience string) (string, error) {
+	if os.Getenv("BUILDKITE") == "" {
+		return "", ErrProviderUnavailable{Provider: p.Name(), Reason: "BUILDKITE not set"}
+	}
+
+	args := []string{"oidc", "request-token"}
+	if audience != "" {
+		args = append(args, "--audience", audience)
+	}
+
+	cmd := exec.CommandContext(ctx, "buildkite-agent", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s: buildkite-agent oidc request-token failed: %w: %s", p.Name(), err, stderr.String())
+	}
+
+	token := strings.TrimSpace(stdout.String())
+	if token == "" {
+		return "", fmt.Errorf("%s: buildkite-agent oidc request-token returned an empty token", p.Name())
+	}
+
+	return token, nil
+}
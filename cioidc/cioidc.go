@@ -0,0 +1,132 @@
+package cioidc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/docker/docker-credential-helpers/credentials"
+	"github.com/docker/docker-credential-helpers/githubactionsoidc"
+)
+
+// Helper implements credentials.Helper by fetching an OIDC token from
+// whichever CI system's environment it detects and, when an oidc.json entry
+// names a role_arn or DOCKER_OIDC_EXCHANGE=1 is set, exchanging it for a
+// registry credential the same way githubactionsoidc does. It
+// shares its token cache, oidc.json registry config and exchange logic with
+// githubactionsoidc rather than reimplementing them.
+type Helper struct {
+	LogFile *os.File
+}
+
+// Add is a no-op; this helper never persists credentials.
+func (h Helper) Add(creds *credentials.Credentials) error {
+	h.log("Adding credentials for server: %s", creds.ServerURL)
+	return nil
+}
+
+// Delete removes any cached OIDC token for serverURL.
+func (h Helper) Delete(serverURL string) error {
+	h.log("Deleting credentials for server: %s", serverURL)
+
+	oidcAudience, _, _ := h.resolveAudience(serverURL)
+	if err := githubactionsoidc.DeleteCachedToken(oidcAudience, serverURL); err != nil {
+		h.log("Failed to delete cached OIDC token: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// Get is a shim over GetContext using a background context with no
+// deadline of its own.
+func (h Helper) Get(serverURL string) (string, string, error) {
+	return h.GetContext(context.Background(), serverURL)
+}
+
+// GetContext detects the current CI provider and fetches an OIDC token for
+// the audience resolved for serverURL (reusing a cached one when valid). With
+// a matching role_arn entry it returns an ECR authorization token; with
+// DOCKER_OIDC_EXCHANGE=1 it returns a registry token; otherwise, or when
+// either exchange fails, it returns the raw OIDC token with username "oidc".
+// ctx's deadline and cancellation are honored across every HTTP call it makes.
+func (h Helper) GetContext(ctx context.Context, serverURL string) (string, string, error) {
+	oidcAudience, regCfg, hasRegCfg := h.resolveAudience(serverURL)
+
+	oidcToken, ok := githubactionsoidc.LoadCachedToken(oidcAudience, serverURL)
+	if ok {
+		h.log("Using cached OIDC token")
+	} else {
+		provider, token, err := Select(ctx, oidcAudience)
+		if err != nil {
+			h.log("No CI OIDC provider available: %v", err)
+			return "", "", credentials.NewErrCredentialsNotFound()
+		}
+		h.log("Fetched OIDC token from %s", provider.Name())
+		oidcToken = token
+
+		if err := githubactionsoidc.SaveCachedToken(oidcAudience, serverURL, oidcToken); err != nil {
+			h.log("Failed to cache OIDC token: %v", err)
+		}
+	}
+
+	if hasRegCfg && regCfg.RoleArn != "" {
+		username, secret, err := githubactionsoidc.ExchangeForECRToken(ctx, serverURL, regCfg.RoleArn, oidcToken)
+		if err != nil {
+			h.log("AWS role exchange failed, falling back to raw OIDC token: %v", err)
+			return "oidc", oidcToken, nil
+		}
+		h.log("Successfully exchanged OIDC token for an ECR authorization token")
+		return username, secret, nil
+	}
+
+	if os.Getenv("DOCKER_OIDC_EXCHANGE") == "1" {
+		username, secret, err := githubactionsoidc.ExchangeForRegistryToken(ctx, serverURL, oidcToken)
+		if err != nil {
+			h.log("Registry token exchange failed, falling back to raw OIDC token: %v", err)
+			return "oidc", oidcToken, nil
+		}
+		h.log("Successfully exchanged OIDC token for registry token")
+		return username, secret, nil
+	}
+
+	return "oidc", oidcToken, nil
+}
+
+// List returns an empty map; this helper never persists credentials.
+func (h Helper) List() (map[string]string, error) {
+	h.log("Listing credentials")
+	return nil, nil
+}
+
+// resolveAudience returns the effective OIDC audience for serverURL: the
+// oidc.json registry config entry's audience when one matches, falling back
+// to CI_OIDC_AUDIENCE. GetContext and Delete must resolve the audience
+// identically, since it is part of the token cache key.
+func (h Helper) resolveAudience(serverURL string) (string, githubactionsoidc.RegistryConfig, bool) {
+	oidcAudience := os.Getenv("CI_OIDC_AUDIENCE")
+	regCfg, hasRegCfg := h.lookupRegistryConfig(serverURL)
+	if hasRegCfg && regCfg.Audience != "" {
+		oidcAudience = regCfg.Audience
+	}
+	return oidcAudience, regCfg, hasRegCfg
+}
+
+// lookupRegistryConfig consults the oidc.json config file (shared with
+// githubactionsoidc) for an entry matching serverURL.
+func (h Helper) lookupRegistryConfig(serverURL string) (githubactionsoidc.RegistryConfig, bool) {
+	cfg, err := githubactionsoidc.LoadOIDCConfig()
+	if err != nil {
+		h.log("Failed to load OIDC registry config: %v", err)
+		return githubactionsoidc.RegistryConfig{}, false
+	}
+	return githubactionsoidc.LookupRegistryConfig(cfg, serverURL)
+}
+
+func (h Helper) log(format string, args ...interface{}) {
+	if h.LogFile == nil {
+		return
+	}
+	h.LogFile.WriteString(fmt.Sprintf("%s: %s\n", time.Now().UTC().Format(time.RFC3339), fmt.Sprintf(format, args...)))
+}
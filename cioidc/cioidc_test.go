@@ -0,0 +1,136 @@
+package cioidc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/docker-credential-helpers/credentials"
+	"github.com/docker/docker-credential-helpers/githubactionsoidc"
+)
+
+func makeJWT(t *testing.T, exp int64) string {
+	t.Helper()
+	claims, err := json.Marshal(map[string]int64{"exp": exp})
+	if err != nil {
+		t.Fatalf("failed to marshal claims: %v", err)
+	}
+	payload := base64.RawURLEncoding.EncodeToString(claims)
+	return "header." + payload + ".signature"
+}
+
+func TestHelper_GetContext_NoProviderAvailable(t *testing.T) {
+	clearProviderEnv(t)
+	os.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	helper := Helper{}
+	_, _, err := helper.GetContext(context.Background(), "https://registry.example.com")
+	if !credentials.IsErrCredentialsNotFound(err) {
+		t.Fatalf("expected ErrCredentialsNotFound, got %v", err)
+	}
+}
+
+func TestHelper_GetContext_ExchangeDisabledReturnsRawToken(t *testing.T) {
+	clearProviderEnv(t)
+	os.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	token := makeJWT(t, time.Now().Add(time.Hour).Unix())
+	os.Setenv("CIRCLE_OIDC_TOKEN", token)
+	defer os.Unsetenv("CIRCLE_OIDC_TOKEN")
+
+	helper := Helper{}
+	username, secret, err := helper.GetContext(context.Background(), "https://registry.example.com")
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if username != "oidc" {
+		t.Fatalf("expected username 'oidc', got %q", username)
+	}
+	if secret != token {
+		t.Fatalf("expected raw OIDC token as secret, got %q", secret)
+	}
+}
+
+func TestHelper_GetContext_ExchangeFallbackOnFailure(t *testing.T) {
+	clearProviderEnv(t)
+	os.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	os.Setenv("DOCKER_OIDC_EXCHANGE", "1")
+	defer os.Unsetenv("DOCKER_OIDC_EXCHANGE")
+
+	token := makeJWT(t, time.Now().Add(time.Hour).Unix())
+	os.Setenv("CIRCLE_OIDC_TOKEN", token)
+	defer os.Unsetenv("CIRCLE_OIDC_TOKEN")
+
+	helper := Helper{}
+	// No real registry is listening, so the exchange ping fails and the
+	// helper should fall back to handing back the raw OIDC token rather
+	// than returning ErrCredentialsNotFound.
+	username, secret, err := helper.GetContext(context.Background(), "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("expected fallback to succeed, got error: %v", err)
+	}
+	if username != "oidc" {
+		t.Fatalf("expected username 'oidc', got %q", username)
+	}
+	if secret != token {
+		t.Fatalf("expected fallback secret %q, got %q", token, secret)
+	}
+}
+
+func TestHelper_GetContext_UsesCachedToken(t *testing.T) {
+	clearProviderEnv(t)
+	os.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	serverURL := "https://registry.example.com"
+	token := makeJWT(t, time.Now().Add(time.Hour).Unix())
+	if err := githubactionsoidc.SaveCachedToken("", serverURL, token); err != nil {
+		t.Fatalf("failed to seed cache: %v", err)
+	}
+
+	// No provider env vars are set at all; a cache hit must be used
+	// instead of falling through to Select.
+	helper := Helper{}
+	_, secret, err := helper.GetContext(context.Background(), serverURL)
+	if err != nil {
+		t.Fatalf("expected cached token to be used, got error: %v", err)
+	}
+	if secret != token {
+		t.Fatalf("expected cached token %q, got %q", token, secret)
+	}
+}
+
+func TestHelper_Delete_UsesRegistryConfigAudience(t *testing.T) {
+	os.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	configPath := filepath.Join(t.TempDir(), "oidc.json")
+	if err := os.WriteFile(configPath, []byte(`{"registries":{"registry.example.com":{"audience":"config-audience"}}}`), 0644); err != nil {
+		t.Fatalf("failed to write oidc config: %v", err)
+	}
+	os.Setenv("DOCKER_OIDC_CONFIG", configPath)
+	defer os.Unsetenv("DOCKER_OIDC_CONFIG")
+
+	serverURL := "https://registry.example.com"
+	token := makeJWT(t, time.Now().Add(time.Hour).Unix())
+	if err := githubactionsoidc.SaveCachedToken("config-audience", serverURL, token); err != nil {
+		t.Fatalf("failed to seed cache: %v", err)
+	}
+
+	helper := Helper{}
+	if err := helper.Delete(serverURL); err != nil {
+		t.Fatalf("expected Delete to succeed, got %v", err)
+	}
+
+	if _, ok := githubactionsoidc.LoadCachedToken("config-audience", serverURL); ok {
+		t.Fatal("expected Delete to purge the entry keyed on the registry config audience")
+	}
+}
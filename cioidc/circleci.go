@@ -0,0 +1,21 @@
+package cioidc
+
+import (
+	"context"
+	"os"
+)
+
+// circleCIProvider reads the OIDC token CircleCI injects into every job via
+// the CIRCLE_OIDC_TOKEN env var. CircleCI does not support per-request
+// audiences, so the audience parameter is ignored.
+type circleCIProvider struct{}
+
+func (circleCIProvider) Name() string { return "circleci" }
+
+func (p circleCIProvider) FetchIDToken(ctx context.Context, audience string) (string, error) {
+	token := os.Getenv("CIRCLE_OIDC_TOKEN")
+	if token == "" {
+		return "", ErrProviderUnavailable{Provider: p.Name(), Reason: "CIRCLE_OIDC_TOKEN not set"}
+	}
+	return token, nil
+}
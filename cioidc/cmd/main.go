@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path"
+	"time"
+
+	"github.com/docker/docker-credential-helpers/cioidc"
+	"github.com/docker/docker-credential-helpers/credentials"
+)
+
+func main() {
+	logFile, err := os.OpenFile(path.Join(os.TempDir(), "cioidc.log"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		panic(err)
+	}
+	defer logFile.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	credentials.Serve(contextHelper{ctx: ctx, helper: cioidc.Helper{LogFile: logFile}})
+}
+
+// contextHelper adapts Helper's context-aware GetContext to the
+// credentials.Helper interface, which has no room for a context, bounding
+// the whole helper invocation so a hanging CI or registry endpoint cannot
+// wedge the process indefinitely.
+type contextHelper struct {
+	ctx    context.Context
+	helper cioidc.Helper
+}
+
+func (h contextHelper) Add(creds *credentials.Credentials) error { return h.helper.Add(creds) }
+func (h contextHelper) Delete(serverURL string) error            { return h.helper.Delete(serverURL) }
+func (h contextHelper) Get(serverURL string) (string, string, error) {
+	return h.helper.GetContext(h.ctx, serverURL)
+}
+func (h contextHelper) List() (map[string]string, error) { return h.helper.List() }
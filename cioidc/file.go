@@ -0,0 +1,34 @@
+package cioidc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// fileProvider reads an OIDC token from a file path named by the
+// OIDC_TOKEN_FILE env var, such as a Kubernetes projected service-account
+// token mounted into the pod.
+type fileProvider struct{}
+
+func (fileProvider) Name() string { return "file" }
+
+func (p fileProvider) FetchIDToken(ctx context.Context, audience string) (string, error) {
+	path := os.Getenv("OIDC_TOKEN_FILE")
+	if path == "" {
+		return "", ErrProviderUnavailable{Provider: p.Name(), Reason: "OIDC_TOKEN_FILE not set"}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%s: failed to read %s: %w", p.Name(), path, err)
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("%s: %s is empty", p.Name(), path)
+	}
+
+	return token, nil
+}
@@ -0,0 +1,30 @@
+package cioidc
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/docker/docker-credential-helpers/githubactionsoidc"
+)
+
+// githubActionsProvider fetches an OIDC token from GitHub Actions using the
+// ACTIONS_ID_TOKEN_REQUEST_URL/TOKEN env vars injected into every job. The
+// actual request is delegated to githubactionsoidc, which the dedicated
+// GitHub Actions helper also uses, so the two stay in sync.
+type githubActionsProvider struct{}
+
+func (githubActionsProvider) Name() string { return "github-actions" }
+
+func (p githubActionsProvider) FetchIDToken(ctx context.Context, audience string) (string, error) {
+	if os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL") == "" || os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN") == "" {
+		return "", ErrProviderUnavailable{Provider: p.Name(), Reason: "ACTIONS_ID_TOKEN_REQUEST_URL/TOKEN not set"}
+	}
+
+	token, err := githubactionsoidc.FetchOIDCToken(ctx, audience)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", p.Name(), err)
+	}
+
+	return token, nil
+}
@@ -0,0 +1,81 @@
+package cioidc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// gitlabCIProvider reads an OIDC token from the ID_TOKEN_* env var that
+// GitLab CI injects for the `id_tokens:` entry matching the requested
+// audience (e.g. `id_tokens: { ID_TOKEN_SIGSTORE: { aud: sigstore } }`
+// injects ID_TOKEN_SIGSTORE, which is looked up for audience "sigstore").
+// GitLab names the var after the id_tokens key the user picked, so when the
+// audience-derived name isn't set we also accept any ID_TOKEN_* or
+// *_ID_TOKEN variable, but only if exactly one is present — a job minting
+// tokens for more than one audience
+// must follow the ID_TOKEN_<AUDIENCE> naming convention so we don't hand
+// back a token minted for the wrong audience.
+type gitlabCIProvider struct{}
+
+func (gitlabCIProvider) Name() string { return "gitlab-ci" }
+
+func (p gitlabCIProvider) FetchIDToken(ctx context.Context, audience string) (string, error) {
+	if os.Getenv("GITLAB_CI") == "" {
+		return "", ErrProviderUnavailable{Provider: p.Name(), Reason: "GITLAB_CI not set"}
+	}
+
+	if audience != "" {
+		if token := os.Getenv(idTokenEnvName(audience)); token != "" {
+			return token, nil
+		}
+	}
+
+	token, ambiguous := soleIDTokenEnv()
+	if ambiguous {
+		return "", fmt.Errorf("%s: multiple ID_TOKEN_*/*_ID_TOKEN variables are set; name the one for audience %q %s", p.Name(), audience, idTokenEnvName(audience))
+	}
+	if token != "" {
+		return token, nil
+	}
+
+	return "", ErrProviderUnavailable{Provider: p.Name(), Reason: "no ID_TOKEN_* or *_ID_TOKEN variable found; add an id_tokens: entry to the job"}
+}
+
+// idTokenEnvName returns the env var name GitLab's ID_TOKEN_<AUDIENCE>
+// convention would use for audience, uppercased with every character
+// outside [A-Z0-9] replaced by an underscore.
+func idTokenEnvName(audience string) string {
+	norm := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - ('a' - 'A')
+		case (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'):
+			return r
+		default:
+			return '_'
+		}
+	}, audience)
+	return "ID_TOKEN_" + norm
+}
+
+// soleIDTokenEnv scans the environment for variables injected by GitLab's
+// `id_tokens:` job keyword, recognizing both the ID_TOKEN_* prefix and the
+// *_ID_TOKEN suffix convention. It returns the token only if exactly one
+// such variable is set; ambiguous is true if more than one was found.
+func soleIDTokenEnv() (token string, ambiguous bool) {
+	for _, kv := range os.Environ() {
+		name, value, found := strings.Cut(kv, "=")
+		if !found || value == "" {
+			continue
+		}
+		if strings.HasPrefix(name, "ID_TOKEN_") || strings.HasSuffix(name, "_ID_TOKEN") {
+			if token != "" {
+				return "", true
+			}
+			token = value
+		}
+	}
+	return token, false
+}
@@ -0,0 +1,47 @@
+package cioidc
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGitlabProvider_SelectsTokenForAudience(t *testing.T) {
+	clearProviderEnv(t)
+	t.Setenv("GITLAB_CI", "true")
+	t.Setenv("ID_TOKEN_ECR", "ecr-token")
+	t.Setenv("ID_TOKEN_SIGSTORE", "sigstore-token")
+
+	token, err := (gitlabCIProvider{}).FetchIDToken(context.Background(), "ecr")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if token != "ecr-token" {
+		t.Fatalf("expected the token minted for audience %q, got %q", "ecr", token)
+	}
+}
+
+func TestGitlabProvider_AmbiguousWithoutMatchingAudience(t *testing.T) {
+	clearProviderEnv(t)
+	t.Setenv("GITLAB_CI", "true")
+	t.Setenv("ID_TOKEN_ECR", "ecr-token")
+	t.Setenv("ID_TOKEN_SIGSTORE", "sigstore-token")
+
+	_, err := (gitlabCIProvider{}).FetchIDToken(context.Background(), "ghcr")
+	if err == nil {
+		t.Fatal("expected an error when no var matches the audience and more than one ID_TOKEN_* var is set")
+	}
+}
+
+func TestGitlabProvider_FallsBackToSoleIDToken(t *testing.T) {
+	clearProviderEnv(t)
+	t.Setenv("GITLAB_CI", "true")
+	t.Setenv("SIGSTORE_ID_TOKEN", "sigstore-token")
+
+	token, err := (gitlabCIProvider{}).FetchIDToken(context.Background(), "sigstore")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if token != "sigstore-token" {
+		t.Fatalf("expected the sole ID token variable to be used, got %q", token)
+	}
+}
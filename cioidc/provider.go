@@ -0,0 +1,75 @@
+// Package cioidc provides a pluggable OIDC token source abstraction so a
+// single docker-credential-helpers binary can authenticate against OCI
+// registries from any CI system that can mint OIDC tokens, instead of
+// shipping one binary per provider (see githubactionsoidc).
+package cioidc
+
+import "context"
+
+// Provider fetches an OIDC ID token scoped to the given audience from a
+// specific CI system.
+type Provider interface {
+	// Name identifies the provider for logging.
+	Name() string
+	// FetchIDToken returns an OIDC ID token for the given audience, or an
+	// error if this provider cannot mint one (e.g. its environment is not
+	// present).
+	FetchIDToken(ctx context.Context, audience string) (string, error)
+}
+
+// Providers returns every known provider in selection priority order. The
+// first provider whose environment is detected is used.
+func Providers() []Provider {
+	return []Provider{
+		githubActionsProvider{},
+		gitlabCIProvider{},
+		buildkiteProvider{},
+		circleCIProvider{},
+		fileProvider{},
+	}
+}
+
+// ErrProviderUnavailable is returned by a Provider when its expected
+// environment (env vars, agent binary, etc.) is not present.
+type ErrProviderUnavailable struct {
+	Provider string
+	Reason   string
+}
+
+func (e ErrProviderUnavailable) Error() string {
+	return e.Provider + " unavailable: " + e.Reason
+}
+
+// Select returns the first provider from Providers whose environment is
+// detected, by calling each provider's FetchIDToken with audience and skipping
+// those that return ErrProviderUnavailable. Any other error from a provider
+// is returned immediately, together with that provider.
+func Select(ctx context.Context, audience string) (Provider, string, error) {
+	var unavailable []error
+	for _, p := range Providers() {
+		token, err := p.FetchIDToken(ctx, audience)
+		if err == nil {
+			return p, token, nil
+		}
+		if _, ok := err.(ErrProviderUnavailable); ok {
+			unavailable = append(unavailable, err)
+			continue
+		}
+		return p, "", err
+	}
+	return nil, "", ErrNoProviderAvailable{Causes: unavailable}
+}
+
+// ErrNoProviderAvailable is returned when no CI-OIDC provider could be
+// detected in the current environment.
+type ErrNoProviderAvailable struct {
+	Causes []error
+}
+
+func (e ErrNoProviderAvailable) Error() string {
+	msg := "no CI OIDC provider detected"
+	for _, c := range e.Causes {
+		msg += "; " + c.Error()
+	}
+	return msg
+}
@@ -0,0 +1,80 @@
+package cioidc
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func clearProviderEnv(t *testing.T) {
+	t.Helper()
+	vars := []string{
+		"ACTIONS_ID_TOKEN_REQUEST_URL", "ACTIONS_ID_TOKEN_REQUEST_TOKEN",
+		"GITLAB_CI", "BUILDKITE", "CIRCLE_OIDC_TOKEN", "OIDC_TOKEN_FILE",
+	}
+	originals := make(map[string]string, len(vars))
+	for _, v := range vars {
+		originals[v] = os.Getenv(v)
+		os.Unsetenv(v)
+	}
+	t.Cleanup(func() {
+		for v, val := range originals {
+			if val != "" {
+				os.Setenv(v, val)
+			} else {
+				os.Unsetenv(v)
+			}
+		}
+	})
+}
+
+func TestSelect_NoProviderAvailable(t *testing.T) {
+	clearProviderEnv(t)
+
+	_, _, err := Select(context.Background(), "registry.example.com")
+	if err == nil {
+		t.Fatal("expected an error when no CI environment is detected")
+	}
+	if _, ok := err.(ErrNoProviderAvailable); !ok {
+		t.Fatalf("expected ErrNoProviderAvailable, got %T: %v", err, err)
+	}
+}
+
+func TestSelect_CircleCI(t *testing.T) {
+	clearProviderEnv(t)
+	os.Setenv("CIRCLE_OIDC_TOKEN", "circle-token")
+
+	provider, token, err := Select(context.Background(), "registry.example.com")
+	if err != nil {
+		t.Fatalf("expected a provider to be selected, got error: %v", err)
+	}
+	if provider.Name() != "circleci" {
+		t.Fatalf("expected circleci provider, got %s", provider.Name())
+	}
+	if token != "circle-token" {
+		t.Fatalf("expected token 'circle-token', got '%s'", token)
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	clearProviderEnv(t)
+
+	f, err := os.CreateTemp(t.TempDir(), "token")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	if _, err := f.WriteString("  file-token  \n"); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+	f.Close()
+
+	os.Setenv("OIDC_TOKEN_FILE", f.Name())
+
+	token, err := (fileProvider{}).FetchIDToken(context.Background(), "registry.example.com")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if token != "file-token" {
+		t.Fatalf("expected 'file-token', got '%s'", token)
+	}
+}
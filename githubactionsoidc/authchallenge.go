@@ -0,0 +1,71 @@
+package githubactionsoidc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// bearerChallenge holds the realm/service/scope parameters advertised by a
+// registry's "WWW-Authenticate: Bearer ..." challenge, per the Docker
+// Registry v2 token authentication spec. Parsing follows the same approach
+// as the old registry/client/authchallenge.go in moby.
+type bearerChallenge struct {
+	Realm   string
+	Service string
+	Scope   string
+}
+
+// parseBearerChallenge parses the value of a WWW-Authenticate header and
+// returns the parameters of its Bearer challenge.
+func parseBearerChallenge(header string) (*bearerChallenge, error) {
+	scheme, rest, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found || !strings.EqualFold(scheme, "bearer") {
+		return nil, fmt.Errorf("unsupported WWW-Authenticate challenge: %q", header)
+	}
+
+	challenge := &bearerChallenge{}
+	for _, pair := range splitChallengeParams(rest) {
+		key, value, found := strings.Cut(pair, "=")
+		if !found {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "realm":
+			challenge.Realm = value
+		case "service":
+			challenge.Service = value
+		case "scope":
+			challenge.Scope = value
+		}
+	}
+
+	if challenge.Realm == "" {
+		return nil, fmt.Errorf("bearer challenge is missing realm parameter: %q", header)
+	}
+	return challenge, nil
+}
+
+// splitChallengeParams splits the comma-separated key="value" pairs of a
+// challenge, ignoring commas that appear inside quoted values.
+func splitChallengeParams(s string) []string {
+	var params []string
+	var current strings.Builder
+	inQuotes := false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			current.WriteRune(r)
+		case r == ',' && !inQuotes:
+			params = append(params, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		params = append(params, current.String())
+	}
+	return params
+}
@@ -0,0 +1,36 @@
+package githubactionsoidc
+
+import "testing"
+
+func TestParseBearerChallenge(t *testing.T) {
+	t.Run("valid bearer challenge", func(t *testing.T) {
+		header := `Bearer realm="https://auth.example.com/token",service="registry.example.com",scope="repository:foo/bar:pull"`
+		challenge, err := parseBearerChallenge(header)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if challenge.Realm != "https://auth.example.com/token" {
+			t.Fatalf("unexpected realm: %s", challenge.Realm)
+		}
+		if challenge.Service != "registry.example.com" {
+			t.Fatalf("unexpected service: %s", challenge.Service)
+		}
+		if challenge.Scope != "repository:foo/bar:pull" {
+			t.Fatalf("unexpected scope: %s", challenge.Scope)
+		}
+	})
+
+	t.Run("missing realm", func(t *testing.T) {
+		header := `Bearer service="registry.example.com"`
+		if _, err := parseBearerChallenge(header); err == nil {
+			t.Fatal("expected error for missing realm parameter")
+		}
+	})
+
+	t.Run("non-bearer scheme", func(t *testing.T) {
+		header := `Basic realm="registry.example.com"`
+		if _, err := parseBearerChallenge(header); err == nil {
+			t.Fatal("expected error for non-bearer scheme")
+		}
+	})
+}
@@ -0,0 +1,250 @@
+package githubactionsoidc
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ecrHostPattern extracts the region out of an ECR registry hostname, e.g.
+// "123456789012.dkr.ecr.us-east-1.amazonaws.com".
+var ecrHostPattern = regexp.MustCompile(`\.dkr\.ecr\.([a-z0-9-]+)\.amazonaws\.com(\.cn)?$`)
+
+// stsEndpoint and ecrEndpoint build the STS and ECR request URLs for region.
+// They are variables so tests can point them at an httptest server instead
+// of the real AWS endpoints.
+var stsEndpoint = func(region string) string {
+	return fmt.Sprintf("https://sts.%s.amazonaws.com/", region)
+}
+var ecrEndpoint = func(region string) string {
+	return fmt.Sprintf("https://ecr.%s.amazonaws.com/", region)
+}
+
+// stsCredentials holds the temporary credentials returned by
+// AssumeRoleWithWebIdentity.
+type stsCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// ExchangeForECRToken assumes roleArn via AWS STS AssumeRoleWithWebIdentity
+// using oidcToken, then calls ECR GetAuthorizationToken with the resulting
+// credentials, returning the decoded "AWS:<password>" basic-auth pair. Both
+// calls are made directly over httpClient rather than the AWS SDK, keeping
+// this helper dependency-free.
+func ExchangeForECRToken(ctx context.Context, serverURL, roleArn, oidcToken string) (string, string, error) {
+	region, ok := ecrRegion(serverURL)
+	if !ok {
+		return "", "", fmt.Errorf("could not determine AWS region from registry host %q", registryHost(serverURL))
+	}
+
+	creds, err := assumeRoleWithWebIdentity(ctx, region, roleArn, oidcToken)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to assume role %s: %w", roleArn, err)
+	}
+
+	return getECRAuthorizationToken(ctx, region, creds)
+}
+
+// ecrRegion extracts the AWS region from an ECR registry server URL.
+func ecrRegion(serverURL string) (string, bool) {
+	match := ecrHostPattern.FindStringSubmatch(registryHost(serverURL))
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+// assumeRoleWithWebIdentity calls STS's AssumeRoleWithWebIdentity action.
+// Unlike most AWS APIs this one does not require a signed request, so it
+// can be called directly as a plain form POST.
+func assumeRoleWithWebIdentity(ctx context.Context, region, roleArn, oidcToken string) (stsCredentials, error) {
+	endpoint := stsEndpoint(region)
+
+	form := url.Values{}
+	form.Set("Action", "AssumeRoleWithWebIdentity")
+	form.Set("Version", "2011-06-15")
+	form.Set("RoleArn", roleArn)
+	form.Set("RoleSessionName", "docker-credential-helper")
+	form.Set("WebIdentityToken", oidcToken)
+	form.Set("DurationSeconds", "3600")
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return stsCredentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/xml")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return stsCredentials{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return stsCredentials{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return stsCredentials{}, fmt.Errorf("AssumeRoleWithWebIdentity failed with status %d: %s", resp.StatusCode, body)
+	}
+
+	var parsed struct {
+		Result struct {
+			Credentials struct {
+				AccessKeyID     string `xml:"AccessKeyId"`
+				SecretAccessKey string `xml:"SecretAccessKey"`
+				SessionToken    string `xml:"SessionToken"`
+			} `xml:"Credentials"`
+		} `xml:"AssumeRoleWithWebIdentityResult"`
+	}
+	if err := xml.Unmarshal(body, &parsed); err != nil {
+		return stsCredentials{}, fmt.Errorf("failed to decode AssumeRoleWithWebIdentity response: %w", err)
+	}
+
+	return stsCredentials{
+		AccessKeyID:     parsed.Result.Credentials.AccessKeyID,
+		SecretAccessKey: parsed.Result.Credentials.SecretAccessKey,
+		SessionToken:    parsed.Result.Credentials.SessionToken,
+	}, nil
+}
+
+// getECRAuthorizationToken calls ECR's GetAuthorizationToken action,
+// signing the request with AWS Signature Version 4 using creds.
+func getECRAuthorizationToken(ctx context.Context, region string, creds stsCredentials) (string, string, error) {
+	endpoint := ecrEndpoint(region)
+	body := []byte("{}")
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "AmazonEC2ContainerRegistry_V20150921.GetAuthorizationToken")
+
+	signSigV4(req, body, creds, region, "ecr", time.Now().UTC())
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("GetAuthorizationToken failed with status %d: %s", resp.StatusCode, respBody)
+	}
+
+	var parsed struct {
+		AuthorizationData []struct {
+			AuthorizationToken string `json:"authorizationToken"`
+		} `json:"authorizationData"`
+	}
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return "", "", fmt.Errorf("failed to decode GetAuthorizationToken response: %w", err)
+	}
+	if len(parsed.AuthorizationData) == 0 || parsed.AuthorizationData[0].AuthorizationToken == "" {
+		return "", "", fmt.Errorf("ECR returned no authorization data")
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(parsed.AuthorizationData[0].AuthorizationToken)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decode ECR authorization token: %w", err)
+	}
+
+	username, password, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return "", "", fmt.Errorf("unexpected ECR authorization token format")
+	}
+
+	return username, password, nil
+}
+
+// signSigV4 signs req in place with AWS Signature Version 4, per
+// https://docs.aws.amazon.com/general/latest/gr/signature-version-4.html.
+// It covers exactly the request shape getECRAuthorizationToken builds: a
+// JSON POST with no query string. now is taken as a parameter, rather than
+// read internally, so tests can sign against a fixed clock.
+func signSigV4(req *http.Request, body []byte, creds stsCredentials, region, service string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("Host", req.URL.Host)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	signedHeaderNames := []string{"content-type", "host", "x-amz-date", "x-amz-target"}
+	if creds.SessionToken != "" {
+		signedHeaderNames = append(signedHeaderNames, "x-amz-security-token")
+	}
+	sort.Strings(signedHeaderNames)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range signedHeaderNames {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, strings.TrimSpace(req.Header.Get(name)))
+	}
+	signedHeaders := strings.Join(signedHeaderNames, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		"",
+		canonicalHeaders.String(),
+		signedHeaders,
+		sha256Hex(body),
+	}, "\n")
+
+	credentialScope := strings.Join([]string{dateStamp, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		credentialScope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := sigV4SigningKey(creds.SecretAccessKey, dateStamp, region, service)
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, credentialScope, signedHeaders, signature,
+	))
+}
+
+func sigV4SigningKey(secretKey, dateStamp, region, service string) []byte {
+	kDate := hmacSHA256([]byte("AWS4"+secretKey), dateStamp)
+	kRegion := hmacSHA256(kDate, region)
+	kService := hmacSHA256(kRegion, service)
+	return hmacSHA256(kService, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,165 @@
+package githubactionsoidc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSignSigV4_KnownVector(t *testing.T) {
+	t.Run("without session token", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "https://ecr.us-east-1.amazonaws.com/", nil)
+		if err != nil {
+			t.Fatalf("failed to build request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+		req.Header.Set("X-Amz-Target", "AmazonEC2ContainerRegistry_V20150921.GetAuthorizationToken")
+
+		creds := stsCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY"}
+		now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		signSigV4(req, []byte("{}"), creds, "us-east-1", "ecr", now)
+
+		want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20230101/us-east-1/ecr/aws4_request, " +
+			"SignedHeaders=content-type;host;x-amz-date;x-amz-target, " +
+			"Signature=a096271a1299759849e97afba6876d4e2c76207a8aeb65e6c675a4ad79ad1e39"
+		if got := req.Header.Get("Authorization"); got != want {
+			t.Fatalf("unexpected Authorization header:\ngot:  %s\nwant: %s", got, want)
+		}
+		if got := req.Header.Get("X-Amz-Date"); got != "20230101T000000Z" {
+			t.Fatalf("unexpected X-Amz-Date: %s", got)
+		}
+	})
+
+	t.Run("with session token", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "https://ecr.us-west-2.amazonaws.com/", nil)
+		if err != nil {
+			t.Fatalf("failed to build request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+		req.Header.Set("X-Amz-Target", "AmazonEC2ContainerRegistry_V20150921.GetAuthorizationToken")
+
+		creds := stsCredentials{
+			AccessKeyID:     "AKIDEXAMPLE",
+			SecretAccessKey: "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY",
+			SessionToken:    "EXAMPLESESSIONTOKEN",
+		}
+		now := time.Date(2023, 6, 15, 12, 0, 0, 0, time.UTC)
+
+		signSigV4(req, []byte("{}"), creds, "us-west-2", "ecr", now)
+
+		want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20230615/us-west-2/ecr/aws4_request, " +
+			"SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target, " +
+			"Signature=3ea79522fe69b2f35ce97e85a4ff8a7e2bfd26dbbe144f8b1f07502b15ad805f"
+		if got := req.Header.Get("Authorization"); got != want {
+			t.Fatalf("unexpected Authorization header:\ngot:  %s\nwant: %s", got, want)
+		}
+		if got := req.Header.Get("X-Amz-Security-Token"); got != "EXAMPLESESSIONTOKEN" {
+			t.Fatalf("unexpected X-Amz-Security-Token: %s", got)
+		}
+	})
+}
+
+// assumeRoleResponse mirrors the subset of the AssumeRoleWithWebIdentity XML
+// response assumeRoleWithWebIdentity parses.
+type assumeRoleResponse struct {
+	XMLName struct{}         `xml:"AssumeRoleWithWebIdentityResponse"`
+	Result  assumeRoleResult `xml:"AssumeRoleWithWebIdentityResult"`
+}
+
+type assumeRoleResult struct {
+	Credentials assumeRoleCredentials `xml:"Credentials"`
+}
+
+type assumeRoleCredentials struct {
+	AccessKeyID     string `xml:"AccessKeyId"`
+	SecretAccessKey string `xml:"SecretAccessKey"`
+	SessionToken    string `xml:"SessionToken"`
+}
+
+func TestExchangeForECRToken_STSAndECRRoundTrip(t *testing.T) {
+	oidcToken := "test-oidc-token"
+	roleArn := "arn:aws:iam::123456789012:role/ecr-pull"
+
+	sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if r.Form.Get("Action") != "AssumeRoleWithWebIdentity" {
+			http.Error(w, "unexpected action", http.StatusBadRequest)
+			return
+		}
+		if r.Form.Get("RoleArn") != roleArn {
+			http.Error(w, "unexpected role arn", http.StatusBadRequest)
+			return
+		}
+		if r.Form.Get("WebIdentityToken") != oidcToken {
+			http.Error(w, "unexpected web identity token", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/xml")
+		xml.NewEncoder(w).Encode(assumeRoleResponse{
+			Result: assumeRoleResult{
+				Credentials: assumeRoleCredentials{
+					AccessKeyID:     "ASSUMED-KEY",
+					SecretAccessKey: "ASSUMED-SECRET",
+					SessionToken:    "ASSUMED-SESSION-TOKEN",
+				},
+			},
+		})
+	}))
+	defer sts.Close()
+
+	ecr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "AmazonEC2ContainerRegistry_V20150921.GetAuthorizationToken" {
+			http.Error(w, "unexpected X-Amz-Target", http.StatusBadRequest)
+			return
+		}
+		if r.Header.Get("Authorization") == "" {
+			http.Error(w, "missing SigV4 Authorization header", http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("X-Amz-Security-Token") != "ASSUMED-SESSION-TOKEN" {
+			http.Error(w, "unexpected X-Amz-Security-Token", http.StatusBadRequest)
+			return
+		}
+
+		token := base64.StdEncoding.EncodeToString([]byte("AWS:ecr-password"))
+		fmt.Fprintf(w, `{"authorizationData":[{"authorizationToken":%q}]}`, token)
+	}))
+	defer ecr.Close()
+
+	restoreSTS := stsEndpoint
+	restoreECR := ecrEndpoint
+	stsEndpoint = func(region string) string { return sts.URL }
+	ecrEndpoint = func(region string) string { return ecr.URL }
+	defer func() {
+		stsEndpoint = restoreSTS
+		ecrEndpoint = restoreECR
+	}()
+
+	username, password, err := ExchangeForECRToken(context.Background(), "123456789012.dkr.ecr.us-east-1.amazonaws.com", roleArn, oidcToken)
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if username != "AWS" {
+		t.Fatalf("expected username 'AWS', got %q", username)
+	}
+	if password != "ecr-password" {
+		t.Fatalf("expected password 'ecr-password', got %q", password)
+	}
+}
+
+func TestExchangeForECRToken_NonECRHostIsRejected(t *testing.T) {
+	_, _, err := ExchangeForECRToken(context.Background(), "ghcr.io", "arn:aws:iam::123456789012:role/ecr-pull", "token")
+	if err == nil {
+		t.Fatal("expected an error for a non-ECR registry host")
+	}
+}
@@ -0,0 +1,157 @@
+package githubactionsoidc
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// minCacheTTL is the minimum remaining lifetime a cached token must have to
+// be reused; anything closer to expiry than this is treated as a miss so
+// callers don't hand out a token that expires mid-request.
+const minCacheTTL = 60 * time.Second
+
+// cacheEntry is the on-disk representation of a cached OIDC token.
+type cacheEntry struct {
+	Token string `json:"token"`
+	Exp   int64  `json:"exp"`
+}
+
+// cacheFilePath returns the on-disk path used to cache the OIDC token for a
+// given audience/serverURL pair.
+func cacheFilePath(audience, serverURL string) (string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(audience + "|" + serverURL))
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".json"), nil
+}
+
+// cacheDir returns $XDG_CACHE_HOME/docker-credential-oidc, falling back to
+// the OS default user cache directory when XDG_CACHE_HOME is unset.
+func cacheDir() (string, error) {
+	base := os.Getenv("XDG_CACHE_HOME")
+	if base == "" {
+		var err error
+		base, err = os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(base, "docker-credential-oidc"), nil
+}
+
+// LoadCachedToken returns the cached OIDC token for audience/serverURL if one
+// exists and has more than minCacheTTL left before it expires.
+func LoadCachedToken(audience, serverURL string) (string, bool) {
+	path, err := cacheFilePath(audience, serverURL)
+	if err != nil {
+		return "", false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return "", false
+	}
+	if entry.Token == "" {
+		return "", false
+	}
+
+	if time.Until(time.Unix(entry.Exp, 0)) <= minCacheTTL {
+		return "", false
+	}
+
+	return entry.Token, true
+}
+
+// SaveCachedToken writes token to the cache for audience/serverURL, together
+// with the "exp" claim parsed from the token's JWT payload. Tokens without a
+// parseable exp claim are not cached.
+func SaveCachedToken(audience, serverURL, token string) error {
+	exp, err := jwtExpiry(token)
+	if err != nil {
+		return err
+	}
+
+	path, err := cacheFilePath(audience, serverURL)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(cacheEntry{Token: token, Exp: exp})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".oidc-cache-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// DeleteCachedToken removes the cache entry for audience/serverURL, if any.
+func DeleteCachedToken(audience, serverURL string) error {
+	path, err := cacheFilePath(audience, serverURL)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// jwtExpiry parses the "exp" claim out of a JWT's payload segment, without
+// verifying the token's signature.
+func jwtExpiry(token string) (int64, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("token is not a JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode JWT payload: %w", err)
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return 0, fmt.Errorf("failed to decode JWT claims: %w", err)
+	}
+	if claims.Exp == 0 {
+		return 0, fmt.Errorf("JWT does not have an exp claim")
+	}
+
+	return claims.Exp, nil
+}
@@ -0,0 +1,110 @@
+package githubactionsoidc
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func makeJWT(t *testing.T, exp int64) string {
+	t.Helper()
+	claims, err := json.Marshal(map[string]int64{"exp": exp})
+	if err != nil {
+		t.Fatalf("failed to marshal claims: %v", err)
+	}
+	payload := base64.RawURLEncoding.EncodeToString(claims)
+	return "header." + payload + ".signature"
+}
+
+func TestTokenCache_MissThenHit(t *testing.T) {
+	os.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	if _, ok := LoadCachedToken("aud", "https://registry.example.com"); ok {
+		t.Fatal("expected a cache miss before any token was saved")
+	}
+
+	token := makeJWT(t, time.Now().Add(time.Hour).Unix())
+	if err := SaveCachedToken("aud", "https://registry.example.com", token); err != nil {
+		t.Fatalf("failed to save cached token: %v", err)
+	}
+
+	cached, ok := LoadCachedToken("aud", "https://registry.example.com")
+	if !ok {
+		t.Fatal("expected a cache hit after saving a token")
+	}
+	if cached != token {
+		t.Fatalf("expected cached token %q, got %q", token, cached)
+	}
+
+	// A different audience/serverURL is a distinct cache entry.
+	if _, ok := LoadCachedToken("other-aud", "https://registry.example.com"); ok {
+		t.Fatal("expected a cache miss for a different audience")
+	}
+}
+
+func TestTokenCache_Expired(t *testing.T) {
+	os.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	token := makeJWT(t, time.Now().Add(30*time.Second).Unix())
+	if err := SaveCachedToken("aud", "https://registry.example.com", token); err != nil {
+		t.Fatalf("failed to save cached token: %v", err)
+	}
+
+	if _, ok := LoadCachedToken("aud", "https://registry.example.com"); ok {
+		t.Fatal("expected a cache miss for a token expiring within the TTL window")
+	}
+}
+
+func TestTokenCache_CorruptFile(t *testing.T) {
+	os.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	path, err := cacheFilePath("aud", "https://registry.example.com")
+	if err != nil {
+		t.Fatalf("failed to compute cache path: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatalf("failed to create cache dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("not json"), 0600); err != nil {
+		t.Fatalf("failed to write corrupt cache file: %v", err)
+	}
+
+	if _, ok := LoadCachedToken("aud", "https://registry.example.com"); ok {
+		t.Fatal("expected a cache miss for a corrupt cache file")
+	}
+}
+
+func TestTokenCache_Delete(t *testing.T) {
+	os.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	token := makeJWT(t, time.Now().Add(time.Hour).Unix())
+	if err := SaveCachedToken("aud", "https://registry.example.com", token); err != nil {
+		t.Fatalf("failed to save cached token: %v", err)
+	}
+
+	if err := DeleteCachedToken("aud", "https://registry.example.com"); err != nil {
+		t.Fatalf("failed to delete cached token: %v", err)
+	}
+
+	if _, ok := LoadCachedToken("aud", "https://registry.example.com"); ok {
+		t.Fatal("expected a cache miss after deleting the cached token")
+	}
+
+	// Deleting an already-absent entry should be a no-op, not an error.
+	if err := DeleteCachedToken("aud", "https://registry.example.com"); err != nil {
+		t.Fatalf("expected deleting a missing entry to succeed, got %v", err)
+	}
+}
+
+func TestJWTExpiry_NotAJWT(t *testing.T) {
+	if _, err := jwtExpiry("not-a-jwt"); err == nil {
+		t.Fatal("expected an error for a non-JWT token")
+	}
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"os"
 	"path"
+	"time"
 
 	"github.com/docker/docker-credential-helpers/credentials"
 	"github.com/docker/docker-credential-helpers/githubactionsoidc"
@@ -15,5 +17,24 @@ func main() {
 	}
 	defer logFile.Close()
 
-	credentials.Serve(githubactionsoidc.GitHubActionsOidc{LogFile: logFile})
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	credentials.Serve(contextHelper{ctx: ctx, helper: githubactionsoidc.GitHubActionsOidc{LogFile: logFile}})
+}
+
+// contextHelper adapts GitHubActionsOidc's context-aware GetContext to the
+// credentials.Helper interface, which has no room for a context, bounding
+// the whole helper invocation so a hanging OIDC or registry endpoint cannot
+// wedge the process indefinitely.
+type contextHelper struct {
+	ctx    context.Context
+	helper githubactionsoidc.GitHubActionsOidc
+}
+
+func (h contextHelper) Add(creds *credentials.Credentials) error { return h.helper.Add(creds) }
+func (h contextHelper) Delete(serverURL string) error            { return h.helper.Delete(serverURL) }
+func (h contextHelper) Get(serverURL string) (string, string, error) {
+	return h.helper.GetContext(h.ctx, serverURL)
 }
+func (h contextHelper) List() (map[string]string, error) { return h.helper.List() }
@@ -1,6 +1,7 @@
 package githubactionsoidc
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -23,54 +24,122 @@ func (gh GitHubActionsOidc) Add(creds *credentials.Credentials) error {
 	return nil
 }
 
-// Delete is a no-op for GitHub Actions OIDC helper.
+// Delete removes any cached OIDC token for serverURL; the helper itself is
+// otherwise stateless.
 func (gh GitHubActionsOidc) Delete(serverURL string) error {
 	gh.LogFile.WriteString(fmt.Sprintf("%s: Deleting credentials for server: %s\n", time.Now().UTC().Format(time.RFC3339), serverURL))
+
+	oidcAudience, _, _ := gh.resolveAudience(serverURL)
+	if err := DeleteCachedToken(oidcAudience, serverURL); err != nil {
+		gh.LogFile.WriteString(fmt.Sprintf("%s: Failed to delete cached OIDC token: %v\n", time.Now().UTC().Format(time.RFC3339), err))
+		return err
+	}
+
 	return nil
 }
 
-// Get retrieves OIDC token from GitHub Actions environment.
+// Get retrieves OIDC token from GitHub Actions environment. It is a shim
+// over GetContext using a background context with no deadline of its own.
 func (gh GitHubActionsOidc) Get(serverURL string) (string, string, error) {
+	return gh.GetContext(context.Background(), serverURL)
+}
+
+// GetContext retrieves OIDC token from GitHub Actions environment, honoring
+// ctx's deadline and cancellation across every HTTP call it makes.
+func (gh GitHubActionsOidc) GetContext(ctx context.Context, serverURL string) (string, string, error) {
 	gh.LogFile.WriteString(fmt.Sprintf("%s: Getting OIDC token: %s\n", time.Now().UTC().Format(time.RFC3339), serverURL))
 
+	oidcAudience, regCfg, hasRegCfg := gh.resolveAudience(serverURL)
+
+	oidcToken, ok := LoadCachedToken(oidcAudience, serverURL)
+	if ok {
+		gh.LogFile.WriteString(fmt.Sprintf("%s: Using cached OIDC token\n", time.Now().UTC().Format(time.RFC3339)))
+	} else {
+		var err error
+		oidcToken, err = gh.fetchOIDCToken(ctx, oidcAudience)
+		if err != nil {
+			return "", "", err
+		}
+
+		if err := SaveCachedToken(oidcAudience, serverURL, oidcToken); err != nil {
+			gh.LogFile.WriteString(fmt.Sprintf("%s: Failed to cache OIDC token: %v\n", time.Now().UTC().Format(time.RFC3339), err))
+		}
+	}
+
+	if hasRegCfg && regCfg.RoleArn != "" {
+		username, secret, err := ExchangeForECRToken(ctx, serverURL, regCfg.RoleArn, oidcToken)
+		if err != nil {
+			gh.LogFile.WriteString(fmt.Sprintf("%s: AWS role exchange failed, falling back to raw OIDC token: %v\n", time.Now().UTC().Format(time.RFC3339), err))
+			return "github_actions", oidcToken, nil
+		}
+		gh.LogFile.WriteString(fmt.Sprintf("%s: Successfully exchanged OIDC token for an ECR authorization token\n", time.Now().UTC().Format(time.RFC3339)))
+		return username, secret, nil
+	}
+
+	if os.Getenv("DOCKER_OIDC_EXCHANGE") == "1" {
+		username, secret, err := ExchangeForRegistryToken(ctx, serverURL, oidcToken)
+		if err != nil {
+			gh.LogFile.WriteString(fmt.Sprintf("%s: Registry token exchange failed, falling back to raw OIDC token: %v\n", time.Now().UTC().Format(time.RFC3339), err))
+			return "github_actions", oidcToken, nil
+		}
+		gh.LogFile.WriteString(fmt.Sprintf("%s: Successfully exchanged OIDC token for registry token\n", time.Now().UTC().Format(time.RFC3339)))
+		return username, secret, nil
+	}
+
+	return "github_actions", oidcToken, nil
+}
+
+// fetchOIDCToken requests a fresh OIDC token from the GitHub Actions
+// ACTIONS_ID_TOKEN_REQUEST_URL endpoint, scoped to audience, logging the
+// outcome and converting any failure to ErrCredentialsNotFound.
+func (gh GitHubActionsOidc) fetchOIDCToken(ctx context.Context, audience string) (string, error) {
+	token, err := FetchOIDCToken(ctx, audience)
+	if err != nil {
+		gh.LogFile.WriteString(fmt.Sprintf("%s: %v\n", time.Now().UTC().Format(time.RFC3339), err))
+		return "", credentials.NewErrCredentialsNotFound()
+	}
+
+	gh.LogFile.WriteString(fmt.Sprintf("%s: Successfully retrieved OIDC token: %s\n", time.Now().UTC().Format(time.RFC3339), token))
+	return token, nil
+}
+
+// FetchOIDCToken requests a fresh OIDC token from the GitHub Actions
+// ACTIONS_ID_TOKEN_REQUEST_URL endpoint, scoped to audience. It is exported
+// so other OIDC token sources (e.g. the cioidc package's GitHub Actions
+// provider) can reuse this request instead of reimplementing it.
+func FetchOIDCToken(ctx context.Context, audience string) (string, error) {
 	oidcRequestURL := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL")
 	oidcRequestToken := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
 
 	if oidcRequestURL == "" || oidcRequestToken == "" {
-		gh.LogFile.WriteString(fmt.Sprintf("%s: Missing OIDC request URL or token\n", time.Now().UTC().Format(time.RFC3339)))
-		return "", "", credentials.NewErrCredentialsNotFound()
+		return "", fmt.Errorf("missing OIDC request URL or token")
 	}
 
-	oidcAudience := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_AUDIENCE")
-	if oidcAudience != "" {
+	if audience != "" {
 		// Check if URL already has query parameters
 		separator := "?"
 		if strings.Contains(oidcRequestURL, "?") {
 			separator = "&"
 		}
-		oidcRequestURL = oidcRequestURL + separator + "audience=" + url.QueryEscape(oidcAudience)
-		gh.LogFile.WriteString(fmt.Sprintf("%s: Added OIDC audience to request URL: %s\n", time.Now().UTC().Format(time.RFC3339), oidcRequestURL))
+		oidcRequestURL = oidcRequestURL + separator + "audience=" + url.QueryEscape(audience)
 	}
 
 	// make http get request to oidcRequestUrl with oidcRequestToken as bearer token header
-	req, err := http.NewRequest("GET", oidcRequestURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", oidcRequestURL, nil)
 	if err != nil {
-		gh.LogFile.WriteString(fmt.Sprintf("%s: Failed to create HTTP request: %v\n", time.Now().UTC().Format(time.RFC3339), err))
-		return "", "", credentials.NewErrCredentialsNotFound()
+		return "", fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 	req.Header.Set("Authorization", "Bearer "+oidcRequestToken)
 	req.Header.Set("User-Agent", "Docker-Credential-Helper-GitHubActionsOIDC")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
-		gh.LogFile.WriteString(fmt.Sprintf("%s: Failed to send HTTP request: %v\n", time.Now().UTC().Format(time.RFC3339), err))
-		return "", "", credentials.NewErrCredentialsNotFound()
+		return "", fmt.Errorf("failed to send HTTP request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		gh.LogFile.WriteString(fmt.Sprintf("%s: Received non-OK HTTP status: %d\n", time.Now().UTC().Format(time.RFC3339), resp.StatusCode))
-		return "", "", credentials.NewErrCredentialsNotFound()
+		return "", fmt.Errorf("received non-OK HTTP status: %d", resp.StatusCode)
 	}
 
 	// read the response as json
@@ -78,12 +147,37 @@ func (gh GitHubActionsOidc) Get(serverURL string) (string, string, error) {
 		Value string `json:"value"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&respBody); err != nil {
-		gh.LogFile.WriteString(fmt.Sprintf("%s: Failed to decode HTTP response: %v\n", time.Now().UTC().Format(time.RFC3339), err))
-		return "", "", credentials.NewErrCredentialsNotFound()
+		return "", fmt.Errorf("failed to decode HTTP response: %w", err)
+	}
+	if respBody.Value == "" {
+		return "", fmt.Errorf("OIDC response did not contain a token")
 	}
 
-	gh.LogFile.WriteString(fmt.Sprintf("%s: Successfully retrieved OIDC token: %s\n", time.Now().UTC().Format(time.RFC3339), respBody.Value))
-	return "github_actions", respBody.Value, nil
+	return respBody.Value, nil
+}
+
+// resolveAudience returns the effective OIDC audience for serverURL: the
+// oidc.json registry config entry's audience when one matches, falling back
+// to ACTIONS_ID_TOKEN_REQUEST_AUDIENCE. GetContext and Delete must resolve
+// the audience identically, since it is part of the token cache key.
+func (gh GitHubActionsOidc) resolveAudience(serverURL string) (string, RegistryConfig, bool) {
+	oidcAudience := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_AUDIENCE")
+	regCfg, hasRegCfg := gh.lookupRegistryConfig(serverURL)
+	if hasRegCfg && regCfg.Audience != "" {
+		oidcAudience = regCfg.Audience
+	}
+	return oidcAudience, regCfg, hasRegCfg
+}
+
+// lookupRegistryConfig consults the oidc.json config file (see
+// registryconfig.go) for an entry matching serverURL.
+func (gh GitHubActionsOidc) lookupRegistryConfig(serverURL string) (RegistryConfig, bool) {
+	cfg, err := LoadOIDCConfig()
+	if err != nil {
+		gh.LogFile.WriteString(fmt.Sprintf("%s: Failed to load OIDC registry config: %v\n", time.Now().UTC().Format(time.RFC3339), err))
+		return RegistryConfig{}, false
+	}
+	return LookupRegistryConfig(cfg, serverURL)
 }
 
 // List returns empty map for GitHub Actions OIDC helper (no stored credentials).
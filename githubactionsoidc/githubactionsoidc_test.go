@@ -1,16 +1,25 @@
 package githubactionsoidc
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/docker/docker-credential-helpers/credentials"
 )
 
 func TestGitHubActionsOidcHelper_Get_WithEnvironmentVariables(t *testing.T) {
+	// Point the OIDC token cache at a scratch directory so these tests never
+	// touch (or get confused by) the real user cache.
+	os.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
 	// Set up test environment variables
 	originalRequestURL := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL")
 	originalRequestToken := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
@@ -183,6 +192,133 @@ func TestGitHubActionsOidcHelper_Get_WithEnvironmentVariables(t *testing.T) {
 			t.Fatalf("expected secret '%s', got '%s'", mockToken, secret)
 		}
 	})
+
+	// Test case 8: registry token exchange
+	t.Run("with registry token exchange enabled", func(t *testing.T) {
+		os.Unsetenv("ACTIONS_ID_TOKEN_REQUEST_AUDIENCE")
+		originalExchange := os.Getenv("DOCKER_OIDC_EXCHANGE")
+		defer func() {
+			if originalExchange != "" {
+				os.Setenv("DOCKER_OIDC_EXCHANGE", originalExchange)
+			} else {
+				os.Unsetenv("DOCKER_OIDC_EXCHANGE")
+			}
+		}()
+
+		oidcToken := "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9.test.exchange.token"
+		registryToken := "registry-access-token"
+
+		mux := http.NewServeMux()
+		var registryURL string
+		mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry.example.com"`, registryURL))
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+		mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+			if err := r.ParseForm(); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if r.Form.Get("subject_token") != oidcToken {
+				http.Error(w, "unexpected subject_token", http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"access_token": registryToken})
+		})
+		registry := httptest.NewServer(mux)
+		defer registry.Close()
+		registryURL = registry.URL
+
+		oidcServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			response := map[string]string{"value": oidcToken}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(response)
+		}))
+		defer oidcServer.Close()
+
+		os.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", oidcServer.URL)
+		os.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "test-token")
+		os.Setenv("DOCKER_OIDC_EXCHANGE", "1")
+
+		username, secret, err := helper.Get(registry.URL)
+		if err != nil {
+			t.Fatalf("expected successful token exchange, got error: %v", err)
+		}
+		if username != "<token>" {
+			t.Fatalf("expected username '<token>', got '%s'", username)
+		}
+		if secret != registryToken {
+			t.Fatalf("expected secret '%s', got '%s'", registryToken, secret)
+		}
+	})
+
+	// Test case 9: registry token exchange falls back to raw OIDC token on failure
+	t.Run("registry token exchange falls back on failure", func(t *testing.T) {
+		os.Unsetenv("ACTIONS_ID_TOKEN_REQUEST_AUDIENCE")
+		originalExchange := os.Getenv("DOCKER_OIDC_EXCHANGE")
+		defer func() {
+			if originalExchange != "" {
+				os.Setenv("DOCKER_OIDC_EXCHANGE", originalExchange)
+			} else {
+				os.Unsetenv("DOCKER_OIDC_EXCHANGE")
+			}
+		}()
+
+		oidcToken := "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9.test.fallback.token"
+		// This server has no /v2/ route, so the challenge lookup fails and
+		// Get should fall back to returning the raw OIDC token.
+		registry := httptest.NewServer(http.NotFoundHandler())
+		defer registry.Close()
+
+		oidcServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			response := map[string]string{"value": oidcToken}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(response)
+		}))
+		defer oidcServer.Close()
+
+		os.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", oidcServer.URL)
+		os.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "test-token")
+		os.Setenv("DOCKER_OIDC_EXCHANGE", "1")
+
+		username, secret, err := helper.Get(registry.URL)
+		if err != nil {
+			t.Fatalf("expected fallback to succeed, got error: %v", err)
+		}
+		if username != "github_actions" {
+			t.Fatalf("expected username 'github_actions', got '%s'", username)
+		}
+		if secret != oidcToken {
+			t.Fatalf("expected fallback secret '%s', got '%s'", oidcToken, secret)
+		}
+	})
+}
+
+func TestGitHubActionsOidcHelper_GetContext_CanceledContext(t *testing.T) {
+	os.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	oidcServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := map[string]string{"value": "token"}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer oidcServer.Close()
+
+	os.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", oidcServer.URL)
+	os.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "test-token")
+	defer func() {
+		os.Unsetenv("ACTIONS_ID_TOKEN_REQUEST_URL")
+		os.Unsetenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+	}()
+
+	helper := GitHubActionsOidc{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := helper.GetContext(ctx, "https://registry.example.com")
+	if !credentials.IsErrCredentialsNotFound(err) {
+		t.Fatalf("expected ErrCredentialsNotFound for a canceled context, got %v", err)
+	}
 }
 
 func TestGitHubActionsOidcHelper_Add(t *testing.T) {
@@ -201,12 +337,48 @@ func TestGitHubActionsOidcHelper_Add(t *testing.T) {
 }
 
 func TestGitHubActionsOidcHelper_Delete(t *testing.T) {
+	os.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
 	helper := GitHubActionsOidc{}
 
-	// Delete should be a no-op and return nil
+	// Delete should succeed even when there is nothing cached.
 	err := helper.Delete("https://registry.example.com")
 	if err != nil {
-		t.Fatalf("expected Delete to return nil (no-op), got %v", err)
+		t.Fatalf("expected Delete to succeed with no cached token, got %v", err)
+	}
+}
+
+func TestGitHubActionsOidcHelper_Delete_UsesRegistryConfigAudience(t *testing.T) {
+	os.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	os.Setenv("ACTIONS_ID_TOKEN_REQUEST_AUDIENCE", "env-audience")
+	defer os.Unsetenv("ACTIONS_ID_TOKEN_REQUEST_AUDIENCE")
+
+	configPath := filepath.Join(t.TempDir(), "oidc.json")
+	if err := os.WriteFile(configPath, []byte(`{"registries":{"registry.example.com":{"audience":"config-audience"}}}`), 0644); err != nil {
+		t.Fatalf("failed to write oidc config: %v", err)
+	}
+	os.Setenv("DOCKER_OIDC_CONFIG", configPath)
+	defer os.Unsetenv("DOCKER_OIDC_CONFIG")
+
+	serverURL := "https://registry.example.com"
+
+	// Seed the cache the same way GetContext would: keyed on the registry
+	// config's audience, not the environment variable's.
+	token := makeJWT(t, time.Now().Add(time.Hour).Unix())
+	if err := SaveCachedToken("config-audience", serverURL, token); err != nil {
+		t.Fatalf("failed to seed cache: %v", err)
+	}
+
+	helper := GitHubActionsOidc{}
+	if err := helper.Delete(serverURL); err != nil {
+		t.Fatalf("expected Delete to succeed, got %v", err)
+	}
+
+	if _, ok := LoadCachedToken("config-audience", serverURL); ok {
+		t.Fatal("expected Delete to purge the entry keyed on the registry config audience")
 	}
 }
 
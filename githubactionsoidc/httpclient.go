@@ -0,0 +1,23 @@
+package githubactionsoidc
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// httpClient is used for every outbound request this package makes (the
+// GitHub OIDC endpoint, the registry challenge ping, the token exchange
+// realm, and the AWS STS and ECR endpoints). It has finite timeouts end-to-end, including proxy-aware dialing,
+// so a hanging endpoint can't wedge the helper process indefinitely.
+var httpClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: 10 * time.Second,
+	},
+}
@@ -0,0 +1,93 @@
+package githubactionsoidc
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RegistryConfig is the per-registry OIDC configuration read from the oidc.json
+// config file: which audience to request a token for, and, for registries
+// that need an AWS exchange (e.g. ECR), which role to assume.
+type RegistryConfig struct {
+	Audience string `json:"audience"`
+	RoleArn  string `json:"role_arn,omitempty"`
+}
+
+// OIDCConfig is the top-level shape of oidc.json.
+type OIDCConfig struct {
+	Registries map[string]RegistryConfig `json:"registries"`
+}
+
+// LoadOIDCConfig reads the per-registry OIDC config file. The path defaults
+// to $DOCKER_CONFIG/oidc.json (DOCKER_CONFIG itself defaulting to
+// ~/.docker), overridable via DOCKER_OIDC_CONFIG. A missing file is not an
+// error; callers should treat it the same as an empty config.
+func LoadOIDCConfig() (*OIDCConfig, error) {
+	path := os.Getenv("DOCKER_OIDC_CONFIG")
+	if path == "" {
+		dir := os.Getenv("DOCKER_CONFIG")
+		if dir == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, err
+			}
+			dir = filepath.Join(home, ".docker")
+		}
+		path = filepath.Join(dir, "oidc.json")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &OIDCConfig{}, nil
+		}
+		return nil, err
+	}
+
+	var cfg OIDCConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// LookupRegistryConfig returns the RegistryConfig matching serverURL, trying
+// an exact host match first and then glob patterns (e.g.
+// "*.dkr.ecr.*.amazonaws.com") in sorted order for determinism.
+func LookupRegistryConfig(cfg *OIDCConfig, serverURL string) (RegistryConfig, bool) {
+	host := registryHost(serverURL)
+
+	if entry, ok := cfg.Registries[host]; ok {
+		return entry, true
+	}
+
+	patterns := make([]string, 0, len(cfg.Registries))
+	for pattern := range cfg.Registries {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(pattern, host); matched {
+			return cfg.Registries[pattern], true
+		}
+	}
+
+	return RegistryConfig{}, false
+}
+
+// registryHost extracts the host (no scheme, no path) from a registry
+// server URL, which may or may not include a scheme.
+func registryHost(serverURL string) string {
+	host := serverURL
+	if idx := strings.Index(host, "://"); idx != -1 {
+		host = host[idx+3:]
+	}
+	if idx := strings.IndexByte(host, '/'); idx != -1 {
+		host = host[:idx]
+	}
+	return host
+}
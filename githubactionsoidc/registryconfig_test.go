@@ -0,0 +1,56 @@
+package githubactionsoidc
+
+import "testing"
+
+func TestRegistryHost(t *testing.T) {
+	cases := map[string]string{
+		"https://ghcr.io":             "ghcr.io",
+		"https://ghcr.io/v2/":         "ghcr.io",
+		"registry.example.com":        "registry.example.com",
+		"registry.example.com:5000":   "registry.example.com:5000",
+		"http://registry.example.com": "registry.example.com",
+	}
+	for in, want := range cases {
+		if got := registryHost(in); got != want {
+			t.Errorf("registryHost(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLookupRegistryConfig(t *testing.T) {
+	cfg := &OIDCConfig{
+		Registries: map[string]RegistryConfig{
+			"ghcr.io": {Audience: "ghcr.io"},
+			"*.dkr.ecr.*.amazonaws.com": {
+				Audience: "sts.amazonaws.com",
+				RoleArn:  "arn:aws:iam::123456789012:role/ecr-pull",
+			},
+		},
+	}
+
+	t.Run("exact match", func(t *testing.T) {
+		entry, ok := LookupRegistryConfig(cfg, "https://ghcr.io")
+		if !ok {
+			t.Fatal("expected a match for ghcr.io")
+		}
+		if entry.Audience != "ghcr.io" {
+			t.Fatalf("unexpected audience: %s", entry.Audience)
+		}
+	})
+
+	t.Run("glob match", func(t *testing.T) {
+		entry, ok := LookupRegistryConfig(cfg, "https://123456789012.dkr.ecr.us-east-1.amazonaws.com")
+		if !ok {
+			t.Fatal("expected a glob match for an ECR registry")
+		}
+		if entry.RoleArn != "arn:aws:iam::123456789012:role/ecr-pull" {
+			t.Fatalf("unexpected role arn: %s", entry.RoleArn)
+		}
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		if _, ok := LookupRegistryConfig(cfg, "https://docker.io"); ok {
+			t.Fatal("expected no match for an unconfigured registry")
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package githubactionsoidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ExchangeForRegistryToken exchanges an OIDC JWT for a registry-scoped access
+// token. It pings serverURL's /v2/ endpoint to discover the registry's Bearer
+// token realm, service and scope, then performs an OAuth2 token-exchange
+// request (RFC 8693) against that realm, mirroring the Docker IdentityToken
+// flow from moby PR #20970.
+func ExchangeForRegistryToken(ctx context.Context, serverURL, oidcToken string) (string, string, error) {
+	challenge, err := fetchBearerChallenge(ctx, serverURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:token-exchange")
+	form.Set("subject_token", oidcToken)
+	form.Set("subject_token_type", "urn:ietf:params:oauth:token-type:id_token")
+	if challenge.Service != "" {
+		form.Set("service", challenge.Service)
+	}
+	if challenge.Scope != "" {
+		form.Set("scope", challenge.Scope)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", challenge.Realm, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "Docker-Credential-Helper-GitHubActionsOIDC")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("token exchange with %s failed with status %d", challenge.Realm, resp.StatusCode)
+	}
+
+	var tokenResp struct {
+		AccessToken   string `json:"access_token"`
+		Token         string `json:"token"`
+		RefreshToken  string `json:"refresh_token"`
+		IdentityToken string `json:"identity_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", "", fmt.Errorf("failed to decode token exchange response: %w", err)
+	}
+
+	// Prefer a refresh/identity token when the registry issues one, so the
+	// caller can keep using it across logins the way docker login does.
+	secret := tokenResp.RefreshToken
+	if secret == "" {
+		secret = tokenResp.IdentityToken
+	}
+	if secret == "" {
+		secret = tokenResp.AccessToken
+	}
+	if secret == "" {
+		secret = tokenResp.Token
+	}
+	if secret == "" {
+		return "", "", fmt.Errorf("token exchange response from %s did not contain a usable token", challenge.Realm)
+	}
+
+	return "<token>", secret, nil
+}
+
+// fetchBearerChallenge pings serverURL's /v2/ endpoint and parses the Bearer
+// challenge from the resulting WWW-Authenticate header.
+func fetchBearerChallenge(ctx context.Context, serverURL string) (*bearerChallenge, error) {
+	pingURL := strings.TrimRight(normalizeServerURL(serverURL), "/") + "/v2/"
+
+	req, err := http.NewRequestWithContext(ctx, "GET", pingURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Docker-Credential-Helper-GitHubActionsOIDC")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	header := resp.Header.Get("WWW-Authenticate")
+	if header == "" {
+		return nil, fmt.Errorf("%s did not return a WWW-Authenticate challenge", pingURL)
+	}
+
+	return parseBearerChallenge(header)
+}
+
+// normalizeServerURL adds an "https://" scheme to serverURL if it doesn't
+// already have one. Docker invokes credential helpers with a bare registry
+// host (e.g. "ghcr.io"), which http.NewRequestWithContext cannot dial as-is.
+func normalizeServerURL(serverURL string) string {
+	if strings.Contains(serverURL, "://") {
+		return serverURL
+	}
+	return "https://" + serverURL
+}
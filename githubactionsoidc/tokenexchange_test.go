@@ -0,0 +1,19 @@
+package githubactionsoidc
+
+import "testing"
+
+func TestNormalizeServerURL(t *testing.T) {
+	t.Run("adds scheme to bare host", func(t *testing.T) {
+		got := normalizeServerURL("ghcr.io")
+		if got != "https://ghcr.io" {
+			t.Fatalf("unexpected result: %s", got)
+		}
+	})
+
+	t.Run("leaves existing scheme alone", func(t *testing.T) {
+		got := normalizeServerURL("http://registry.example.com")
+		if got != "http://registry.example.com" {
+			t.Fatalf("unexpected result: %s", got)
+		}
+	})
+}